@@ -0,0 +1,150 @@
+package lux
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// defaultCompressMinSize is the response body size, in bytes, at or above which
+// Compress will attempt to compress a response when no MinSize option is
+// given. Below this, compression overhead tends to outweigh the benefit.
+const defaultCompressMinSize = 1400
+
+type (
+	// CompressOption configures the behavior of the Compress middleware.
+	CompressOption func(*compressConfig)
+
+	compressConfig struct {
+		minSize int
+		brotli  bool
+	}
+
+	// compressSettings is stashed on the responseWriter by Compress so that
+	// getResponse can compress the body once the handler has finished writing
+	// to it.
+	compressSettings struct {
+		encoding string
+		minSize  int
+	}
+)
+
+// MinSize sets the minimum response body size, in bytes, before Compress will
+// attempt to compress a response. Defaults to 1400 bytes.
+func MinSize(bytes int) CompressOption {
+	return func(c *compressConfig) {
+		c.minSize = bytes
+	}
+}
+
+// Brotli enables brotli compression for clients that advertise "br" support
+// via Accept-Encoding, preferred over gzip when both are accepted.
+func Brotli() CompressOption {
+	return func(c *compressConfig) {
+		c.brotli = true
+	}
+}
+
+// Compress returns a middleware that transparently compresses response bodies
+// above a configurable size threshold, choosing brotli (if enabled via the
+// Brotli option) or gzip based on the request's Accept-Encoding header.
+// Because API Gateway's Lambda proxy integration requires binary response
+// payloads to be base64 encoded, a compressed response also has
+// IsBase64Encoded set so API Gateway decodes it before forwarding it to the
+// client.
+func Compress(opts ...CompressOption) HandlerFunc {
+	cfg := &compressConfig{minSize: defaultCompressMinSize}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(w ResponseWriter, req *Request) {
+		rw, ok := w.(*responseWriter)
+		if !ok {
+			return
+		}
+
+		encoding := acceptedEncoding(req.Headers, cfg.brotli)
+		if encoding == "" {
+			return
+		}
+
+		rw.compress = &compressSettings{encoding: encoding, minSize: cfg.minSize}
+	}
+}
+
+// acceptedEncoding picks the best content encoding to use for a response
+// given the request's Accept-Encoding header, preferring brotli over gzip
+// when both are accepted and allowBrotli is true.
+func acceptedEncoding(headers map[string]string, allowBrotli bool) string {
+	accept := headerValue(headers, "Accept-Encoding")
+
+	if allowBrotli && strings.Contains(accept, "br") {
+		return "br"
+	}
+
+	if strings.Contains(accept, "gzip") {
+		return "gzip"
+	}
+
+	return ""
+}
+
+// headerValue looks up a header by name, ignoring case, since API Gateway
+// does not guarantee canonical casing for incoming headers.
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// compressBody compresses body using the given content encoding ("gzip" or
+// "br"), returning false if the encoding is unrecognized.
+func compressBody(body []byte, encoding string) ([]byte, bool) {
+	var buf bytes.Buffer
+
+	switch encoding {
+	case "br":
+		bw := brotli.NewWriter(&buf)
+
+		if _, err := bw.Write(body); err != nil {
+			return nil, false
+		}
+
+		if err := bw.Close(); err != nil {
+			return nil, false
+		}
+	case "gzip":
+		gw := gzip.NewWriter(&buf)
+
+		if _, err := gw.Write(body); err != nil {
+			return nil, false
+		}
+
+		if err := gw.Close(); err != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	return buf.Bytes(), true
+}
+
+// encodeBody returns body as a string, base64 encoding it first if isBase64
+// is true.
+func encodeBody(body []byte, isBase64 bool) string {
+	if isBase64 {
+		return base64.StdEncoding.EncodeToString(body)
+	}
+
+	return string(body)
+}
@@ -0,0 +1,209 @@
+package lux
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestCompressGzipsResponseAboveMinSize(t *testing.T) {
+	r := NewRouter()
+	r.Middleware(Compress(MinSize(10)))
+
+	body := strings.Repeat("a", 20)
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}).Path("/big")
+
+	req := newGatewayRequest(http.MethodGet, "/big")
+	req.Headers["Accept-Encoding"] = "gzip"
+
+	resp, err := r.ServeAPIGateway(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !resp.IsBase64Encoded {
+		t.Fatal("got IsBase64Encoded false, want true for a compressed body")
+	}
+
+	if got := resp.Headers["Content-Encoding"]; got != "gzip" {
+		t.Fatalf("got Content-Encoding %q, want %q", got, "gzip")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to base64-decode response body: %v", err)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(decoded))
+	if err != nil {
+		t.Fatalf("failed to open gzip reader: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(gr); err != nil {
+		t.Fatalf("failed to read gzip body: %v", err)
+	}
+
+	if buf.String() != body {
+		t.Fatalf("got decompressed body %q, want %q", buf.String(), body)
+	}
+}
+
+func TestCompressPrefersBrotliWhenEnabled(t *testing.T) {
+	r := NewRouter()
+	r.Middleware(Compress(MinSize(10), Brotli()))
+
+	body := strings.Repeat("b", 20)
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}).Path("/big")
+
+	req := newGatewayRequest(http.MethodGet, "/big")
+	req.Headers["Accept-Encoding"] = "gzip, br"
+
+	resp, err := r.ServeAPIGateway(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := resp.Headers["Content-Encoding"]; got != "br" {
+		t.Fatalf("got Content-Encoding %q, want %q (brotli should be preferred over gzip)", got, "br")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to base64-decode response body: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(brotli.NewReader(bytes.NewReader(decoded))); err != nil {
+		t.Fatalf("failed to read brotli body: %v", err)
+	}
+
+	if buf.String() != body {
+		t.Fatalf("got decompressed body %q, want %q", buf.String(), body)
+	}
+}
+
+func TestCompressLeavesSmallResponsesUncompressed(t *testing.T) {
+	r := NewRouter()
+	r.Middleware(Compress(MinSize(1000)))
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("small"))
+	}).Path("/small")
+
+	req := newGatewayRequest(http.MethodGet, "/small")
+	req.Headers["Accept-Encoding"] = "gzip"
+
+	resp, err := r.ServeAPIGateway(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.IsBase64Encoded {
+		t.Fatal("got IsBase64Encoded true, want false: body is below MinSize and should not be compressed")
+	}
+
+	if _, ok := resp.Headers["Content-Encoding"]; ok {
+		t.Fatal("got a Content-Encoding header, want none for an uncompressed response")
+	}
+
+	if resp.Body != "small" {
+		t.Fatalf("got body %q, want %q", resp.Body, "small")
+	}
+}
+
+func TestCompressSkipsResponseWithoutAcceptEncoding(t *testing.T) {
+	r := NewRouter()
+	r.Middleware(Compress(MinSize(1)))
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(strings.Repeat("c", 20)))
+	}).Path("/big")
+
+	resp, err := r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/big"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.IsBase64Encoded {
+		t.Fatal("got IsBase64Encoded true, want false: no Accept-Encoding was sent")
+	}
+}
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		allowBrotli bool
+		want        string
+	}{
+		{"no header", "", false, ""},
+		{"gzip only", "gzip", false, "gzip"},
+		{"brotli disabled falls back to gzip", "br, gzip", false, "gzip"},
+		{"brotli preferred when enabled", "gzip, br", true, "br"},
+		{"unsupported encoding", "deflate", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.accept != "" {
+				headers["Accept-Encoding"] = tt.accept
+			}
+
+			if got := acceptedEncoding(headers, tt.allowBrotli); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressBody(t *testing.T) {
+	if _, ok := compressBody([]byte("hello"), "deflate"); ok {
+		t.Fatal("got ok true for an unrecognized encoding, want false")
+	}
+
+	compressed, ok := compressBody([]byte("hello"), "gzip")
+	if !ok {
+		t.Fatal("got ok false for gzip, want true")
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("failed to open gzip reader: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(gr); err != nil {
+		t.Fatalf("failed to read gzip body: %v", err)
+	}
+
+	if buf.String() != "hello" {
+		t.Fatalf("got decompressed body %q, want %q", buf.String(), "hello")
+	}
+}
+
+func TestEncodeBody(t *testing.T) {
+	if got := encodeBody([]byte("hello"), false); got != "hello" {
+		t.Fatalf("got %q, want %q", got, "hello")
+	}
+
+	if got := encodeBody([]byte("hello"), true); got != base64.StdEncoding.EncodeToString([]byte("hello")) {
+		t.Fatalf("got %q, want base64-encoded body", got)
+	}
+}
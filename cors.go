@@ -0,0 +1,189 @@
+package lux
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type (
+	// CORSOption configures the behavior of the CORS middleware.
+	CORSOption func(*corsConfig)
+
+	corsConfig struct {
+		allowedOrigins   []string
+		allowedMethods   []string
+		allowedHeaders   []string
+		exposedHeaders   []string
+		allowCredentials bool
+		maxAge           int
+		originValidator  func(string) bool
+	}
+)
+
+// AllowedOrigins sets the list of origins a CORS request is allowed to
+// originate from. A "*" entry allows any origin. If not set, all origins are
+// allowed.
+func AllowedOrigins(origins ...string) CORSOption {
+	return func(c *corsConfig) {
+		c.allowedOrigins = origins
+	}
+}
+
+// AllowedMethods sets the list of HTTP methods allowed in a CORS preflight
+// response. Defaults to GET, HEAD, and POST.
+func AllowedMethods(methods ...string) CORSOption {
+	return func(c *corsConfig) {
+		c.allowedMethods = methods
+	}
+}
+
+// AllowedHeaders sets the list of request headers allowed in a CORS
+// preflight response. If not set, the headers requested via
+// Access-Control-Request-Headers are echoed back.
+func AllowedHeaders(headers ...string) CORSOption {
+	return func(c *corsConfig) {
+		c.allowedHeaders = headers
+	}
+}
+
+// ExposedHeaders sets the list of response headers made available to
+// client-side scripts via Access-Control-Expose-Headers.
+func ExposedHeaders(headers ...string) CORSOption {
+	return func(c *corsConfig) {
+		c.exposedHeaders = headers
+	}
+}
+
+// AllowCredentials sets Access-Control-Allow-Credentials, allowing cookies
+// and other credentials to be included in cross-origin requests. A wildcard
+// AllowedOrigins cannot be combined with AllowCredentials per the CORS spec,
+// so the allowed origin is always echoed back verbatim once this is set.
+func AllowCredentials() CORSOption {
+	return func(c *corsConfig) {
+		c.allowCredentials = true
+	}
+}
+
+// MaxAge sets, in seconds, how long a preflight response can be cached by
+// the client via Access-Control-Max-Age.
+func MaxAge(seconds int) CORSOption {
+	return func(c *corsConfig) {
+		c.maxAge = seconds
+	}
+}
+
+// OriginValidator sets a callback used to decide whether a request's Origin
+// header is allowed, overriding AllowedOrigins.
+func OriginValidator(fn func(string) bool) CORSOption {
+	return func(c *corsConfig) {
+		c.originValidator = fn
+	}
+}
+
+// CORS returns a middleware that handles Cross-Origin Resource Sharing.
+// Preflight requests (an OPTIONS request carrying
+// Access-Control-Request-Method) are short-circuited with the appropriate
+// Access-Control-Allow-* headers and never reach the route handler; actual
+// requests have the relevant headers added and otherwise proceed as normal.
+// Unlike a standard Go server, API Gateway always dispatches OPTIONS
+// requests to the Lambda, so preflight detection is done here rather than
+// relying on net/http's own OPTIONS handling.
+func CORS(opts ...CORSOption) HandlerFunc {
+	cfg := &corsConfig{
+		allowedOrigins: []string{"*"},
+		allowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodPost},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(w ResponseWriter, req *Request) {
+		origin := headerValue(req.Headers, "Origin")
+
+		if origin == "" || !cfg.originAllowed(origin) {
+			return
+		}
+
+		headers := w.Header()
+		headers.Set("Access-Control-Allow-Origin", cfg.allowOriginValue(origin))
+		headers.Set("Vary", "Origin")
+
+		if cfg.allowCredentials {
+			headers.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !cfg.isPreflight(req) {
+			if len(cfg.exposedHeaders) > 0 {
+				headers.Set("Access-Control-Expose-Headers", strings.Join(cfg.exposedHeaders, ", "))
+			}
+
+			return
+		}
+
+		headers.Set("Access-Control-Allow-Methods", strings.Join(cfg.allowedMethods, ", "))
+		headers.Set("Access-Control-Allow-Headers", strings.Join(cfg.requestedHeaders(req), ", "))
+
+		if cfg.maxAge > 0 {
+			headers.Set("Access-Control-Max-Age", strconv.Itoa(cfg.maxAge))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// isPreflight reports whether req is a CORS preflight request, detected via
+// the presence of Access-Control-Request-Method rather than the method alone
+// since API Gateway forwards every OPTIONS request to the Lambda regardless
+// of whether it originated from a CORS preflight.
+func (c *corsConfig) isPreflight(req *Request) bool {
+	return req.Method == http.MethodOptions && headerValue(req.Headers, "Access-Control-Request-Method") != ""
+}
+
+// requestedHeaders returns the headers to advertise as allowed in a
+// preflight response: the configured AllowedHeaders if set, otherwise the
+// headers the client asked for via Access-Control-Request-Headers.
+func (c *corsConfig) requestedHeaders(req *Request) []string {
+	if len(c.allowedHeaders) > 0 {
+		return c.allowedHeaders
+	}
+
+	if requested := headerValue(req.Headers, "Access-Control-Request-Headers"); requested != "" {
+		return strings.Split(requested, ", ")
+	}
+
+	return nil
+}
+
+// originAllowed reports whether origin is allowed per the originValidator
+// callback, if set, or the configured allowedOrigins otherwise.
+func (c *corsConfig) originAllowed(origin string) bool {
+	if c.originValidator != nil {
+		return c.originValidator(origin)
+	}
+
+	for _, allowed := range c.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowOriginValue returns the value to use for Access-Control-Allow-Origin:
+// a literal "*" when any origin is allowed and credentials aren't in play,
+// otherwise the specific requesting origin, since a wildcard cannot be
+// combined with Access-Control-Allow-Credentials.
+func (c *corsConfig) allowOriginValue(origin string) string {
+	if !c.allowCredentials {
+		for _, allowed := range c.allowedOrigins {
+			if allowed == "*" {
+				return "*"
+			}
+		}
+	}
+
+	return origin
+}
@@ -0,0 +1,68 @@
+package lux
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCORSPreflightShortCircuitsBeforeRouting(t *testing.T) {
+	r := NewRouter()
+	r.CORS(AllowedOrigins("https://example.com"), AllowedMethods(http.MethodGet))
+
+	helloCalled := false
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		helloCalled = true
+		w.WriteHeader(http.StatusOK)
+	}).Path("/hello")
+
+	req := newGatewayRequest(http.MethodOptions, "/hello")
+	req.Headers["Origin"] = "https://example.com"
+	req.Headers["Access-Control-Request-Method"] = http.MethodGet
+
+	resp, err := r.ServeAPIGateway(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("got status %d, want 204 for a CORS preflight short-circuited before routing", resp.StatusCode)
+	}
+
+	if helloCalled {
+		t.Fatal("preflight request reached the downstream route handler, it should have been short-circuited")
+	}
+
+	if got := resp.Headers["Access-Control-Allow-Origin"]; got != "https://example.com" {
+		t.Fatalf("got Access-Control-Allow-Origin %q, want the requesting origin echoed back", got)
+	}
+
+	if got := resp.Headers["Access-Control-Allow-Methods"]; got != http.MethodGet {
+		t.Fatalf("got Access-Control-Allow-Methods %q, want %q", got, http.MethodGet)
+	}
+}
+
+func TestCORSActualRequestProceedsToHandler(t *testing.T) {
+	r := NewRouter()
+	r.CORS(AllowedOrigins("https://example.com"))
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Path("/hello")
+
+	req := newGatewayRequest(http.MethodGet, "/hello")
+	req.Headers["Origin"] = "https://example.com"
+
+	resp, err := r.ServeAPIGateway(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200 for a non-preflight CORS request reaching the handler", resp.StatusCode)
+	}
+
+	if got := resp.Headers["Access-Control-Allow-Origin"]; got != "https://example.com" {
+		t.Fatalf("got Access-Control-Allow-Origin %q, want the requesting origin echoed back", got)
+	}
+}
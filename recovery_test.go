@@ -0,0 +1,92 @@
+package lux
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestCustomRecoveryRunsAfterRecoveryAndControlsResponse(t *testing.T) {
+	r := NewRouter()
+
+	var order []string
+
+	r.Recovery(func(info PanicInfo) {
+		order = append(order, "recovery")
+
+		if info.Error == nil || info.Error.Error() != "boom" {
+			t.Fatalf("got PanicInfo.Error %v, want %q", info.Error, "boom")
+		}
+
+		if len(info.Stack) == 0 {
+			t.Fatal("got empty PanicInfo.Stack, want a captured stack trace")
+		}
+	})
+
+	r.CustomRecovery(func(w ResponseWriter, req *Request, info PanicInfo) {
+		order = append(order, "customRecovery")
+
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("recovered: " + info.Error.Error()))
+	})
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		panic("boom")
+	}).Path("/panics")
+
+	resp, err := r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/panics"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"recovery", "customRecovery"}; len(order) != len(want) || order[0] != want[0] || order[1] != want[1] {
+		t.Fatalf("got callback order %v, want %v", order, want)
+	}
+
+	if resp.StatusCode != http.StatusTeapot {
+		t.Fatalf("got status %d, want %d from CustomRecovery", resp.StatusCode, http.StatusTeapot)
+	}
+
+	if resp.Body != "recovered: boom" {
+		t.Fatalf("got body %q, want %q", resp.Body, "recovered: boom")
+	}
+}
+
+func TestPanicWithoutCustomRecoveryFallsBackToDefault500(t *testing.T) {
+	r := NewRouter()
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		panic("boom")
+	}).Path("/panics")
+
+	resp, err := r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/panics"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("got status %d, want %d when no recovery handler writes a response", resp.StatusCode, http.StatusInternalServerError)
+	}
+}
+
+func TestPanicWithErrorValuePreservesErrorInPanicInfo(t *testing.T) {
+	r := NewRouter()
+
+	var got error
+
+	r.Recovery(func(info PanicInfo) {
+		got = info.Error
+	})
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		panic(bytes.ErrTooLarge)
+	}).Path("/panics")
+
+	if _, err := r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/panics")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != bytes.ErrTooLarge {
+		t.Fatalf("got PanicInfo.Error %v, want the original error value preserved", got)
+	}
+}
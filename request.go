@@ -0,0 +1,170 @@
+package lux
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type (
+	// Request represents an incoming HTTP request, normalized from whichever
+	// underlying Lambda event triggered the function (REST API Gateway, HTTP
+	// API Gateway, or an Application Load Balancer), so that handlers and
+	// middleware can be written once and served from any of them. The
+	// original event is retained and can be recovered via Request.Raw for
+	// source-specific needs.
+	Request struct {
+		Method          string
+		Path            string
+		Headers         map[string]string
+		Query           map[string]string
+		Body            string
+		RequestID       string
+		SourceIP        string
+		PathParams      map[string]string
+		IsBase64Encoded bool
+
+		ctx context.Context
+		raw interface{}
+	}
+
+	// Response represents an outgoing HTTP response, normalized across the
+	// event sources lux can adapt from. The Router.Serve* method that handled
+	// the request converts it back to the response shape its source expects.
+	Response struct {
+		StatusCode      int
+		Headers         map[string]string
+		Body            string
+		IsBase64Encoded bool
+	}
+)
+
+// Raw returns the original Lambda event this request was adapted from, e.g.
+// an events.APIGatewayProxyRequest. Callers that need source-specific data
+// the normalized fields don't capture (an ALB target group ARN, an HTTP API
+// JWT claim) should type-assert the result to the event type of whichever
+// Router.Serve* method is in use.
+func (req *Request) Raw() interface{} {
+	return req.raw
+}
+
+// Context returns the request's context, defaulting to context.Background()
+// if no middleware has set one via WithContext. Middleware can attach values
+// here (an auth principal, a tracing span, a DB handle) for handlers further
+// down the chain to read.
+func (req *Request) Context() context.Context {
+	if req.ctx == nil {
+		return context.Background()
+	}
+
+	return req.ctx
+}
+
+// WithContext sets the context associated with the request, returning req to
+// allow chaining. It mirrors net/http's Request.WithContext so
+// context-carrying middleware can be written in a familiar style, but since
+// lux threads a single *Request through the whole middleware chain (unlike
+// net/http's handler wrapping), it mutates req in place instead of returning
+// a copy — downstream middleware and the handler see the new context.
+func (req *Request) WithContext(ctx context.Context) *Request {
+	if ctx == nil {
+		panic("lux: nil context")
+	}
+
+	req.ctx = ctx
+
+	return req
+}
+
+// PathParam returns the value of the named path parameter, or an empty string
+// if there is none with that name. For a route with a Path pattern this is the
+// value the pattern captured; for a route without one it is the value supplied
+// by the event source (e.g. API Gateway's PathParameters).
+func (req *Request) PathParam(name string) string {
+	return req.PathParams[name]
+}
+
+// newRequestFromAPIGateway adapts a REST API Gateway (v1) proxy integration
+// event into a normalized Request.
+func newRequestFromAPIGateway(req events.APIGatewayProxyRequest) Request {
+	return Request{
+		Method:          req.HTTPMethod,
+		Path:            req.Path,
+		Headers:         req.Headers,
+		Query:           req.QueryStringParameters,
+		Body:            req.Body,
+		RequestID:       req.RequestContext.RequestID,
+		SourceIP:        req.RequestContext.Identity.SourceIP,
+		PathParams:      req.PathParameters,
+		IsBase64Encoded: req.IsBase64Encoded,
+		raw:             req,
+	}
+}
+
+// newRequestFromAPIGatewayV2 adapts an HTTP API Gateway (v2) event into a
+// normalized Request.
+func newRequestFromAPIGatewayV2(req events.APIGatewayV2HTTPRequest) Request {
+	return Request{
+		Method:          req.RequestContext.HTTP.Method,
+		Path:            req.RawPath,
+		Headers:         req.Headers,
+		Query:           req.QueryStringParameters,
+		Body:            req.Body,
+		RequestID:       req.RequestContext.RequestID,
+		SourceIP:        req.RequestContext.HTTP.SourceIP,
+		PathParams:      req.PathParameters,
+		IsBase64Encoded: req.IsBase64Encoded,
+		raw:             req,
+	}
+}
+
+// newRequestFromALB adapts an Application Load Balancer target group event
+// into a normalized Request. ALB assigns no request ID and performs no path
+// parameter extraction, so both are left empty.
+func newRequestFromALB(req events.ALBTargetGroupRequest) Request {
+	return Request{
+		Method:          req.HTTPMethod,
+		Path:            req.Path,
+		Headers:         req.Headers,
+		Query:           req.QueryStringParameters,
+		Body:            req.Body,
+		IsBase64Encoded: req.IsBase64Encoded,
+		raw:             req,
+	}
+}
+
+// toAPIGateway converts a normalized Response into the response shape a REST
+// API Gateway (v1) proxy integration expects.
+func (resp Response) toAPIGateway() events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode:      resp.StatusCode,
+		Headers:         resp.Headers,
+		Body:            resp.Body,
+		IsBase64Encoded: resp.IsBase64Encoded,
+	}
+}
+
+// toAPIGatewayV2 converts a normalized Response into the response shape an
+// HTTP API Gateway (v2) integration expects.
+func (resp Response) toAPIGatewayV2() events.APIGatewayV2HTTPResponse {
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:      resp.StatusCode,
+		Headers:         resp.Headers,
+		Body:            resp.Body,
+		IsBase64Encoded: resp.IsBase64Encoded,
+	}
+}
+
+// toALB converts a normalized Response into the response shape an
+// Application Load Balancer target group integration expects.
+func (resp Response) toALB() events.ALBTargetGroupResponse {
+	return events.ALBTargetGroupResponse{
+		StatusCode:        resp.StatusCode,
+		StatusDescription: fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		Headers:           resp.Headers,
+		Body:              resp.Body,
+		IsBase64Encoded:   resp.IsBase64Encoded,
+	}
+}
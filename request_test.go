@@ -0,0 +1,99 @@
+package lux
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func newGatewayV2Request(method, path string) events.APIGatewayV2HTTPRequest {
+	req := events.APIGatewayV2HTTPRequest{
+		RawPath: path,
+		Headers: map[string]string{},
+	}
+	req.RequestContext.HTTP.Method = method
+
+	return req
+}
+
+func newALBRequest(method, path string) events.ALBTargetGroupRequest {
+	return events.ALBTargetGroupRequest{
+		HTTPMethod: method,
+		Path:       path,
+		Headers:    map[string]string{},
+	}
+}
+
+func TestServeAPIGatewayRoundTrip(t *testing.T) {
+	r := NewRouter()
+
+	var gotRequestID, gotSourceIP string
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		gotRequestID = req.RequestID
+		gotSourceIP = req.SourceIP
+		w.WriteHeader(http.StatusOK)
+	}).Path("/ping")
+
+	req := newGatewayRequest(http.MethodGet, "/ping")
+	req.RequestContext.RequestID = "req-123"
+	req.RequestContext.Identity.SourceIP = "1.2.3.4"
+
+	resp, err := r.ServeAPIGateway(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", resp.StatusCode)
+	}
+
+	if gotRequestID != "req-123" {
+		t.Fatalf("got RequestID %q, want %q", gotRequestID, "req-123")
+	}
+
+	if gotSourceIP != "1.2.3.4" {
+		t.Fatalf("got SourceIP %q, want %q", gotSourceIP, "1.2.3.4")
+	}
+}
+
+func TestServeAPIGatewayV2RoundTrip(t *testing.T) {
+	r := NewRouter()
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(req.PathParam("id")))
+	}).Path("/users/:id")
+
+	resp, err := r.ServeAPIGatewayV2(newGatewayV2Request(http.MethodGet, "/users/42"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || resp.Body != "42" {
+		t.Fatalf("got status %d body %q, want 200 body \"42\"", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestServeALBRoundTrip(t *testing.T) {
+	r := NewRouter()
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Path("/widgets")
+
+	resp, err := r.ServeALB(newALBRequest(http.MethodGet, "/widgets"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("got status %d, want 404", resp.StatusCode)
+	}
+
+	if want := fmt.Sprintf("%d %s", http.StatusNotFound, http.StatusText(http.StatusNotFound)); resp.StatusDescription != want {
+		t.Fatalf("got StatusDescription %q, want %q", resp.StatusDescription, want)
+	}
+}
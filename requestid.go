@@ -0,0 +1,50 @@
+package lux
+
+import (
+	"context"
+
+	"github.com/segmentio/ksuid"
+)
+
+// contextKey is an unexported type for context keys defined in this package,
+// so they cannot collide with keys defined in other packages.
+type contextKey int
+
+// requestIDContextKey is the context key RequestID stores the resolved
+// request ID under.
+const requestIDContextKey contextKey = iota
+
+// RequestID returns a middleware that ensures every request can be
+// correlated across logs and downstream calls. It resolves an ID by checking,
+// in order, the incoming X-Request-ID header, the ID assigned by API Gateway
+// (ALB events carry none), and finally a generated KSUID. The ID is stored
+// on the request's context (retrievable with RequestIDFromContext), echoed
+// back to the client via the X-Request-ID response header, and used in place
+// of the router's own requestId log field on the log lines written after
+// middleware has run (request completion and panic recovery). The router's
+// "handling incoming request" line is written before any middleware runs and
+// carries the event source's request ID instead.
+func RequestID() HandlerFunc {
+	return func(w ResponseWriter, req *Request) {
+		id := headerValue(req.Headers, "X-Request-ID")
+
+		if id == "" {
+			id = req.RequestID
+		}
+
+		if id == "" {
+			id = ksuid.New().String()
+		}
+
+		req.WithContext(context.WithValue(req.Context(), requestIDContextKey, id))
+		w.Header().Set("X-Request-ID", id)
+	}
+}
+
+// RequestIDFromContext returns the request ID stored on ctx by the RequestID
+// middleware, or an empty string if that middleware hasn't run.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+
+	return id
+}
@@ -1,5 +1,7 @@
 // Package lux contains types for creating an HTTP router for use in AWS lambda functions. The router supports
-// RESTful HTTP methods & contains configuration for logging, request filtering & panic recovery.
+// RESTful HTTP methods & contains configuration for logging, request filtering & panic recovery. Requests can
+// originate from a REST API Gateway (v1), an HTTP API Gateway (v2), or an Application Load Balancer; see
+// Router.ServeAPIGateway, Router.ServeAPIGatewayV2, and Router.ServeALB.
 package lux
 
 import (
@@ -8,7 +10,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"runtime"
+	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -18,6 +21,7 @@ import (
 
 var (
 	errNotAllowed    = errors.New("not allowed")
+	errNotFound      = errors.New("not found")
 	errNotAcceptable = errors.New("not acceptable")
 )
 
@@ -25,18 +29,42 @@ type (
 	// The Router type handles incoming requests & routes them to the registered
 	// handlers.
 	Router struct {
-		routes     []*Route
+		routes         []*Route
+		middleware     []HandlerFunc
+		recovery       RecoverFunc
+		customRecovery CustomRecoverFunc
+		log            *logrus.Logger
+	}
+
+	// The Group type represents a set of routes that share a common path prefix
+	// and middleware chain. Groups are created with Router.Group or Group.Group
+	// and allow versioned or isolated sections of an API (e.g. "/v1", "/admin")
+	// to be registered without affecting the router's global middleware.
+	Group struct {
+		router     *Router
+		prefix     string
 		middleware []HandlerFunc
-		recovery   RecoverFunc
-		log        *logrus.Logger
 	}
 
 	// The Route type defines a route that can be used by the router.
 	Route struct {
-		handler HandlerFunc
-		method  string
-		headers map[string]string
-		queries map[string]string
+		handler  HandlerFunc
+		method   string
+		headers  map[string]string
+		queries  map[string]string
+		path     string
+		segments []pathSegment
+	}
+
+	// pathSegmentKind identifies the kind of a single segment of a parsed path
+	// pattern.
+	pathSegmentKind int
+
+	// pathSegment represents a single "/"-delimited piece of a path pattern as
+	// parsed by Route.Path.
+	pathSegment struct {
+		kind  pathSegmentKind
+		value string
 	}
 
 	// The ResponseWriter type allows for interacting with the HTTP response similarly to a triaditional
@@ -61,22 +89,30 @@ type (
 	// The RecoverFunc type defines what a panic recovery function should look like.
 	RecoverFunc func(PanicInfo)
 
-	// The Request type represents an incoming HTTP request.
-	Request events.APIGatewayProxyRequest
-
-	// The Response type represents an outgoing HTTP response.
-	Response events.APIGatewayProxyResponse
+	// The CustomRecoverFunc type defines a panic recovery function that, unlike
+	// RecoverFunc, is given the in-flight ResponseWriter and Request so it can
+	// render a response (e.g. a structured JSON error body) instead of letting
+	// the framework fall through to the default 500.
+	CustomRecoverFunc func(ResponseWriter, *Request, PanicInfo)
 
 	// The Headers type represents the HTTP response headers.
 	Headers map[string]string
 
 	responseWriter struct {
-		code    int
-		headers Headers
-		body    []byte
+		code     int
+		headers  Headers
+		body     []byte
+		compress *compressSettings
 	}
 )
 
+// Segment kinds produced by parsePathPattern.
+const (
+	segmentLiteral pathSegmentKind = iota
+	segmentParam
+	segmentWildcard
+)
+
 // NewRouter creates a new lambda router.
 func NewRouter() *Router {
 	return &Router{
@@ -113,6 +149,25 @@ func (r *Router) Middleware(fn HandlerFunc) *Router {
 	return r
 }
 
+// Group creates a route group rooted at the given path prefix. Middleware passed
+// here runs, in order, before any handler registered on the group (or one of its
+// nested groups), independently of middleware registered globally via
+// Router.Middleware. This allows you to structure versioned APIs ("/v1", "/v2")
+// or isolate middleware such as auth to only the routes that need it.
+func (r *Router) Group(prefix string, middleware ...HandlerFunc) *Group {
+	return &Group{
+		router:     r,
+		prefix:     strings.TrimRight(prefix, "/"),
+		middleware: middleware,
+	}
+}
+
+// CORS registers the CORS middleware on the router with the given options.
+// See the package-level CORS function for the full behavior.
+func (r *Router) CORS(opts ...CORSOption) *Router {
+	return r.Middleware(CORS(opts...))
+}
+
 // Recovery sets a custom recovery handler that allows you to process panics using
 // your own handler. Not providing a recovery handler does not mean that your
 // panics are not handled. When no custom handler is specified your panic
@@ -123,6 +178,18 @@ func (r *Router) Recovery(fn RecoverFunc) *Router {
 	return r
 }
 
+// CustomRecovery sets a custom recovery handler that, unlike Recovery, is
+// given the in-flight ResponseWriter and Request and so can render a response
+// to the client (e.g. a structured JSON body containing a request ID) instead
+// of the framework falling through to the default 500 response. If both
+// Recovery and CustomRecovery are registered, both run, with CustomRecovery
+// running after Recovery so it has the final say over the response.
+func (r *Router) CustomRecovery(fn CustomRecoverFunc) *Router {
+	r.customRecovery = fn
+
+	return r
+}
+
 // Logging sets the output for logs generated by the router. The logging package used
 // is logrus (https://github.com/sirupsen/logrus). All logs written to os.Stdout and
 // os.Stderr will automatically be picked up by CloudWatch. The logrus.Formatter
@@ -135,69 +202,206 @@ func (r *Router) Logging(out io.Writer, format logrus.Formatter) *Router {
 	return r
 }
 
-// ServeHTTP handles an incoming HTTP request from the AWS API Gateway. If
-// the request matches a registered route then the specified handler will be
-// executed after any registered middleware.
+// Handler registers a handler for the given method and path pattern within the
+// group. The pattern is appended to the group's prefix and parsed the same way
+// as Route.Path. The group's middleware chain (and that of any parent groups)
+// runs before fn, regardless of what is registered via Router.Middleware.
+func (g *Group) Handler(method, path string, fn HandlerFunc) *Route {
+	return g.router.Handler(method, g.wrap(fn)).Path(g.prefix + path)
+}
+
+// Middleware adds a middleware function that runs before any handler registered
+// on this group or on nested groups created after this call, after any
+// middleware inherited from a parent group. Nested groups created earlier copied
+// the middleware chain at creation and do not run it.
+func (g *Group) Middleware(fn HandlerFunc) *Group {
+	g.middleware = append(g.middleware, fn)
+
+	return g
+}
+
+// Group creates a nested group whose prefix is appended to this group's prefix
+// and whose middleware chain runs after this group's.
+func (g *Group) Group(prefix string, middleware ...HandlerFunc) *Group {
+	nested := &Group{
+		router:     g.router,
+		prefix:     strings.TrimRight(g.prefix+prefix, "/"),
+		middleware: append([]HandlerFunc{}, g.middleware...),
+	}
+
+	nested.middleware = append(nested.middleware, middleware...)
+
+	return nested
+}
+
+// wrap composes the group's middleware chain with fn into a single HandlerFunc
+// suitable for registration with the underlying Router.
+func (g *Group) wrap(fn HandlerFunc) HandlerFunc {
+	return func(w ResponseWriter, req *Request) {
+		for _, mid := range g.middleware {
+			mid(w, req)
+
+			if rw, ok := w.(*responseWriter); ok && rw.code != 0 {
+				return
+			}
+		}
+
+		fn(w, req)
+	}
+}
+
+// ServeAPIGateway handles an incoming request from a REST API Gateway (v1)
+// proxy integration. See serve for the matching, middleware, and response
+// status behavior shared by all Router.Serve* methods.
+func (r *Router) ServeAPIGateway(req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	resp, err := r.serve(newRequestFromAPIGateway(req))
+
+	return resp.toAPIGateway(), err
+}
+
+// ServeHTTP is an alias for ServeAPIGateway, kept for existing REST API
+// Gateway (v1) consumers (e.g. lambda.Start(router.ServeHTTP)) that predate
+// the v2/ALB Router.Serve* methods.
+func (r *Router) ServeHTTP(req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return r.ServeAPIGateway(req)
+}
+
+// ServeAPIGatewayV2 handles an incoming request from an HTTP API Gateway (v2)
+// integration. See serve for the matching, middleware, and response status
+// behavior shared by all Router.Serve* methods.
+func (r *Router) ServeAPIGatewayV2(req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	resp, err := r.serve(newRequestFromAPIGatewayV2(req))
+
+	return resp.toAPIGatewayV2(), err
+}
+
+// ServeALB handles an incoming request from an Application Load Balancer
+// target group integration. See serve for the matching, middleware, and
+// response status behavior shared by all Router.Serve* methods.
+func (r *Router) ServeALB(req events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+	resp, err := r.serve(newRequestFromALB(req))
+
+	return resp.toALB(), err
+}
+
+// serve routes a normalized Request to a registered handler, regardless of
+// which event source it was adapted from. Registered middleware always runs
+// first, so middleware such as CORS can short-circuit a request (e.g. a
+// preflight OPTIONS) before it is ever subject to route matching; if no
+// middleware writes a response, the request proceeds to the route matched
+// below.
 //
 // If a handler cannot be found matching the HTTP method, a 405 response
 // will be returned to the client.
 //
+// If the method matches but no registered route's path pattern matches the
+// request path, a 404 response will be returned to the client.
+//
 // If you have specified query or header filters to your route, a request
-// that matches the HTTP method but lacks the required parameters/headers
-// will result in a 406 response.
+// that matches the HTTP method and path but lacks the required
+// parameters/headers will result in a 406 response.
 //
 // A panic will result in a 500 response.
-func (r *Router) ServeHTTP(req Request) (Response, error) {
+// A handler registered with CustomRecovery can write a different response.
+func (r *Router) serve(req Request) (Response, error) {
 	ts := time.Now()
 
 	r.log.WithFields(logrus.Fields{
-		"method":    req.HTTPMethod,
-		"params":    req.QueryStringParameters,
-		"requestId": req.RequestContext.RequestID,
+		"method":    req.Method,
+		"params":    req.Query,
+		"requestId": req.RequestID,
 	}).Info("handling incoming request")
 
-	route, err := r.findRoute(req)
-
-	if err == errNotAllowed {
-		return newResponse(err.Error(), http.StatusMethodNotAllowed)
-	}
-
-	if err == errNotAcceptable {
-		return newResponse(err.Error(), http.StatusNotAcceptable)
-	}
-
 	w := &responseWriter{
 		headers: make(Headers),
 		body:    []byte{},
 	}
 
-	r.performRequest(route, w, req)
+	r.performRequest(w, &req)
 
 	resp := w.getResponse()
 
+	requestID := req.RequestID
+
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		requestID = id
+	}
+
 	r.log.WithFields(logrus.Fields{
 		"status":    resp.StatusCode,
 		"duration":  time.Since(ts).String(),
-		"requestId": req.RequestContext.RequestID,
+		"requestId": requestID,
 	}).Info("finished handling request")
 
 	return resp, nil
 }
 
-// performRequest executes any registered middleware before attempting to use the route's
-// handler & will recover from any panics.
-func (r *Router) performRequest(route *Route, w *responseWriter, req Request) {
-	defer r.recover(req)
+// performRequest runs the router's global middleware, then resolves and invokes the
+// matching route's handler, recovering from any panic along the way. Middleware runs
+// before route matching: a middleware like CORS needs to short-circuit a preflight
+// OPTIONS request, and handlers are normally only registered per resource method (not
+// per OPTIONS), so matching first would reject most real preflight requests with a 405
+// before middleware ever saw them. req is shared, by pointer, with every middleware and
+// the handler, so context values and other in-place mutations made by one are visible to
+// the next.
+func (r *Router) performRequest(w *responseWriter, req *Request) {
+	defer r.recover(w, req)
 
 	// Run any registered middleware
 	for _, mid := range r.middleware {
-		// Return a response if the middleware warrants it
-		if mid(w, &req); w.code != 0 {
+		// Stop and use the response as-is if the middleware warrants it.
+		if mid(w, req); w.code != 0 {
 			return
 		}
 	}
 
-	route.handler(w, &req)
+	route, params, err := r.findRoute(*req)
+
+	if err == errNotAllowed {
+		writeResponse(w, err.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err == errNotFound {
+		writeResponse(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err == errNotAcceptable {
+		writeResponse(w, err.Error(), http.StatusNotAcceptable)
+		return
+	}
+
+	// Only apply captured params when the matched route has a path pattern;
+	// otherwise leave whatever the event source adapter already populated
+	// (e.g. a {proxy+} or {id} resource template configured outside lux)
+	// intact instead of overwriting it with nil.
+	if len(route.segments) > 0 {
+		req.PathParams = params
+	}
+
+	route.handler(w, req)
+}
+
+// writeResponse renders data as a JSON body with the given status directly onto w,
+// for use by the routing failure paths in performRequest that run before a route (and
+// thus a handler) has been matched.
+func writeResponse(w *responseWriter, data interface{}, status int) {
+	resp, err := newResponse(data, status)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+
+		return
+	}
+
+	for key, value := range resp.Headers {
+		w.Header().Set(key, value)
+	}
+
+	w.WriteHeader(resp.StatusCode)
+	w.Write([]byte(resp.Body))
 }
 
 // Headers allows you to specify headers a request should have in order to
@@ -218,6 +422,20 @@ func (r *Route) Queries(pairs ...string) *Route {
 	return r
 }
 
+// Path restricts this route to requests whose URL path matches the given
+// pattern. A pattern segment prefixed with ":" captures that segment under
+// the given name (e.g. ":id"), and a segment prefixed with "*" captures the
+// remainder of the path, wildcard included (e.g. "*path"). A wildcard
+// segment must be the last segment in the pattern. Captured values are
+// available to the handler via Request.PathParam. A route with no path
+// pattern configured matches any path.
+func (r *Route) Path(pattern string) *Route {
+	r.path = pattern
+	r.segments = parsePathPattern(pattern)
+
+	return r
+}
+
 // newResponse creates a new response object with a JSON encoded body and given
 // status code.
 func newResponse(data interface{}, status int) (Response, error) {
@@ -260,50 +478,64 @@ func (h Headers) Set(key, val string) {
 	h[key] = val
 }
 
+// pathCandidate pairs a route whose path pattern matched a request with the
+// path parameters captured during that match.
+type pathCandidate struct {
+	route  *Route
+	params map[string]string
+}
+
 // findRoute attempts to locate a route that can handle a given request and
-// returns errors specifying if no route is found, or the provided headers &
-// parameters for that route are invalid.
-func (r *Router) findRoute(req Request) (*Route, error) {
-	var out *Route
+// returns errors specifying if no route is found for the method, no route's
+// path pattern matches, or the provided headers & parameters for that route
+// are invalid.
+func (r *Router) findRoute(req Request) (*Route, map[string]string, error) {
 	var checkRoutes []*Route
-	var err error
 
 	// Look through each route
 	for _, route := range r.routes {
 		// If the route method matches, add it to the slice.
-		if route.method == req.HTTPMethod {
+		if route.method == req.Method {
 			checkRoutes = append(checkRoutes, route)
 		}
 	}
 
 	// If we got no routes to check, return a 405
 	if len(checkRoutes) == 0 {
-		return nil, errNotAllowed
+		return nil, nil, errNotAllowed
 	}
 
-	// Look at each route with a matching method
+	var candidates []pathCandidate
+
+	// Narrow down to routes whose path pattern matches the request path.
 	for _, route := range checkRoutes {
-		err = route.canRoute(req)
+		if params, ok := route.matchPath(req.Path); ok {
+			candidates = append(candidates, pathCandidate{route: route, params: params})
+		}
+	}
+
+	// If nothing matched the path, return a 404.
+	if len(candidates) == 0 {
+		return nil, nil, errNotFound
+	}
 
-		// If we cannot use this route, check the next one.
-		if err != nil {
+	// Look at each candidate with a matching method & path
+	for _, candidate := range candidates {
+		if err := candidate.route.canRoute(req); err != nil {
 			continue
 		}
 
 		// Otherwise, we found our route
-		out = route
-		err = nil
-		break
+		return candidate.route, candidate.params, nil
 	}
 
-	// If we found a route, 'out' will be non-nil.
-	return out, err
+	return nil, nil, errNotAcceptable
 }
 
 // recover handles panics that may occur during execution of the lambda function. In a situation
-// where a panic does occur, the router will recover and execute a custom panic handler if it has
-// been provided.
-func (r *Router) recover(req Request) {
+// where a panic does occur, the router will recover and execute any registered recovery handlers,
+// in order: Recovery, then CustomRecovery.
+func (r *Router) recover(w *responseWriter, req *Request) {
 	var err error
 
 	// If a panic was thrown
@@ -318,38 +550,119 @@ func (r *Router) recover(req Request) {
 			err = fmt.Errorf("%s", x)
 		}
 
+		requestID := req.RequestID
+
+		if id := RequestIDFromContext(req.Context()); id != "" {
+			requestID = id
+		}
+
 		r.log.WithFields(logrus.Fields{
-			"requestId": req.RequestContext.RequestID,
+			"requestId": requestID,
 			"error":     err.Error(),
 		}).Error("recovered from panic")
 
 		info := PanicInfo{
 			Error:   err,
-			Request: req,
-			Stack:   make([]byte, 1024*8),
+			Request: *req,
+			Stack:   debug.Stack(),
 		}
 
-		runtime.Stack(info.Stack, false)
-
 		// If a custom recover func was defined, use it.
 		if r.recovery != nil {
 			r.recovery(info)
 		}
+
+		// If a response-writing recover func was defined, give it the chance to
+		// render a response before the framework falls through to the default
+		// 500.
+		if r.customRecovery != nil {
+			r.customRecovery(w, req, info)
+		}
 	}
 }
 
 // canRoute determines if a route can handle a given request based on the route's expected headers
 // and parameters.
 func (r *Route) canRoute(req Request) error {
-	if !matchMap(r.headers, req.Headers) || !matchMap(r.queries, req.QueryStringParameters) {
+	if !matchMap(r.headers, req.Headers) || !matchMap(r.queries, req.Query) {
 		return errNotAcceptable
 	}
 
 	return nil
 }
 
+// matchPath determines whether the given request path matches this route's path
+// pattern, returning any path parameters captured in the process. A route with
+// no path pattern configured (the zero value of Route.segments) matches any path.
+func (r *Route) matchPath(path string) (map[string]string, bool) {
+	if r.segments == nil {
+		return nil, true
+	}
+
+	parts := splitPath(path)
+	params := make(map[string]string, len(r.segments))
+
+	for i, seg := range r.segments {
+		switch seg.kind {
+		case segmentWildcard:
+			params[seg.value] = strings.Join(parts[min(i, len(parts)):], "/")
+
+			return params, true
+		case segmentParam:
+			if i >= len(parts) {
+				return nil, false
+			}
+
+			params[seg.value] = parts[i]
+		default:
+			if i >= len(parts) || parts[i] != seg.value {
+				return nil, false
+			}
+		}
+	}
+
+	if len(parts) != len(r.segments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// parsePathPattern parses a route path pattern into its constituent segments,
+// recognizing ":name" captures and a trailing "*name" wildcard capture.
+func parsePathPattern(pattern string) []pathSegment {
+	parts := splitPath(pattern)
+	segments := make([]pathSegment, 0, len(parts))
+
+	for _, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			segments = append(segments, pathSegment{kind: segmentParam, value: part[1:]})
+		case strings.HasPrefix(part, "*"):
+			segments = append(segments, pathSegment{kind: segmentWildcard, value: part[1:]})
+		default:
+			segments = append(segments, pathSegment{kind: segmentLiteral, value: part})
+		}
+	}
+
+	return segments
+}
+
+// splitPath splits a URL path into its non-empty "/"-delimited segments.
+func splitPath(path string) []string {
+	trimmed := strings.Trim(path, "/")
+
+	if trimmed == "" {
+		return nil
+	}
+
+	return strings.Split(trimmed, "/")
+}
+
 // getResponse takes all data written to the response writer and converts it into a Response type
-// that can be returned to the client.
+// that can be returned to the client. If compression was requested by the Compress middleware and
+// the body meets its size threshold, the body is compressed and IsBase64Encoded is set so API
+// Gateway decodes it before forwarding it to the client.
 func (w *responseWriter) getResponse() Response {
 	if w.code == 0 {
 		return Response{
@@ -358,10 +671,22 @@ func (w *responseWriter) getResponse() Response {
 		}
 	}
 
+	body := w.body
+	isBase64Encoded := false
+
+	if w.compress != nil && len(body) >= w.compress.minSize {
+		if compressed, ok := compressBody(body, w.compress.encoding); ok {
+			body = compressed
+			isBase64Encoded = true
+			w.headers.Set("Content-Encoding", w.compress.encoding)
+		}
+	}
+
 	return Response{
-		StatusCode: w.code,
-		Body:       string(w.body),
-		Headers:    w.headers,
+		StatusCode:      w.code,
+		Body:            encodeBody(body, isBase64Encoded),
+		Headers:         w.headers,
+		IsBase64Encoded: isBase64Encoded,
 	}
 }
 
@@ -0,0 +1,166 @@
+package lux
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func newGatewayRequest(method, path string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod: method,
+		Path:       path,
+		Headers:    map[string]string{},
+	}
+}
+
+func TestRoutePathParams(t *testing.T) {
+	r := NewRouter()
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(req.PathParam("id") + "/" + req.PathParam("postID")))
+	}).Path("/users/:id/posts/:postID")
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(req.PathParam("path")))
+	}).Path("/files/*path")
+
+	resp, err := r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/users/42/posts/7"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || resp.Body != "42/7" {
+		t.Fatalf("got status %d body %q, want 200 body \"42/7\"", resp.StatusCode, resp.Body)
+	}
+
+	resp, err = r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/files/a/b/c.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || resp.Body != "a/b/c.txt" {
+		t.Fatalf("got status %d body %q, want 200 body \"a/b/c.txt\"", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestRouteWithoutPathPreservesAdapterPathParameters(t *testing.T) {
+	r := NewRouter()
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(req.PathParam("id")))
+	})
+
+	req := newGatewayRequest(http.MethodGet, "/users/42")
+	req.PathParameters = map[string]string{"id": "42"}
+
+	resp, err := r.ServeAPIGateway(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Body != "42" {
+		t.Fatalf("got body %q, want the API-Gateway-supplied path parameter to survive routing", resp.Body)
+	}
+}
+
+func TestFindRouteStatusPrecedence(t *testing.T) {
+	r := NewRouter()
+
+	r.Handler(http.MethodGet, func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Path("/widgets").Headers("X-Required", "*")
+
+	resp, err := r.ServeAPIGateway(newGatewayRequest(http.MethodPost, "/widgets"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("got status %d, want 405 for a method with no registered route", resp.StatusCode)
+	}
+
+	resp, err = r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/does-not-exist"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("got status %d, want 404 when the method matches but no path pattern does", resp.StatusCode)
+	}
+
+	resp, err = r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/widgets"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("got status %d, want 406 when the path matches but a required header is missing", resp.StatusCode)
+	}
+}
+
+func TestGroupPrefixAndMiddleware(t *testing.T) {
+	r := NewRouter()
+
+	var ran []string
+
+	admin := r.Group("/admin", func(w ResponseWriter, req *Request) {
+		ran = append(ran, "group")
+	})
+
+	admin.Handler(http.MethodGet, "/widgets", func(w ResponseWriter, req *Request) {
+		ran = append(ran, "handler")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/admin/widgets"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200 for a route registered under a group's prefix", resp.StatusCode)
+	}
+
+	if want := []string{"group", "handler"}; len(ran) != len(want) || ran[0] != want[0] || ran[1] != want[1] {
+		t.Fatalf("got execution order %v, want %v", ran, want)
+	}
+}
+
+func TestGroupTrailingSlashPrefixIsTrimmed(t *testing.T) {
+	r := NewRouter()
+
+	r.Group("/admin/").Handler(http.MethodGet, "/widgets", func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/admin/widgets"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200: a trailing slash on a group prefix must not introduce an empty path segment", resp.StatusCode)
+	}
+}
+
+func TestNestedGroupTrailingSlashPrefixIsTrimmed(t *testing.T) {
+	r := NewRouter()
+
+	r.Group("/v1/").Group("/admin/").Handler(http.MethodGet, "/widgets", func(w ResponseWriter, req *Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := r.ServeAPIGateway(newGatewayRequest(http.MethodGet, "/v1/admin/widgets"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200: a trailing slash on a nested group prefix must not introduce an empty path segment", resp.StatusCode)
+	}
+}